@@ -0,0 +1,457 @@
+// Package bitio provides bit-level Reader and Writer types on top of the
+// standard io.Reader and io.Writer interfaces, allowing values of arbitrary
+// bit width to be read from and written to an underlying byte stream.
+package bitio
+
+import "io"
+
+// BitOrder specifies in which order bits are packed into (and unpacked from)
+// the bytes of the underlying stream.
+type BitOrder int
+
+const (
+	// MSBFirst packs bits starting at bit 7 (the most significant bit) of
+	// each byte, moving towards bit 0. This is the default bit order used
+	// by NewReader and NewWriter.
+	MSBFirst BitOrder = iota
+
+	// LSBFirst packs bits starting at bit 0 (the least significant bit) of
+	// each byte, moving towards bit 7. This is the bit order used by
+	// DEFLATE (RFC 1951), gzip and similar formats.
+	LSBFirst
+)
+
+// byteReader is the interface NewReader wraps input sources in
+// when they don't already implement it.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// byteWriter is the interface NewWriter wraps output targets in
+// when they don't already implement it.
+type byteWriter interface {
+	io.Writer
+	io.ByteWriter
+}
+
+// readerWrapper wraps an io.Reader that doesn't implement io.ByteReader.
+type readerWrapper struct {
+	io.Reader
+}
+
+func (r readerWrapper) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
+// writerWrapper wraps an io.Writer that doesn't implement io.ByteWriter.
+type writerWrapper struct {
+	io.Writer
+}
+
+func (w writerWrapper) WriteByte(c byte) error {
+	b := [1]byte{c}
+	_, err := w.Writer.Write(b[:])
+	return err
+}
+
+// Reader is a bit-level reader. It reads bits and bytes from an underlying
+// io.Reader, keeping track of any unread bits left over from the last byte
+// read from the source.
+type Reader struct {
+	in    byteReader
+	order BitOrder
+
+	bitBuf uint64 // accumulator of unread bits, aligned according to order
+	bitCnt uint8  // number of valid, unread bits in bitBuf
+
+	lastVal uint64 // value of the bits most recently consumed, for UnreadBits
+	lastN   uint8  // number of bits most recently consumed, for UnreadBits
+
+	// MaxUnary bounds how many bits ReadUnary (and the codes built on top
+	// of it) will scan before giving up with an error, so a malformed
+	// stream of all-zero bits can't make it loop forever. It defaults to
+	// DefaultMaxUnary.
+	MaxUnary uint64
+
+	bitsRead uint64 // total number of bits returned to callers so far
+
+	err error
+}
+
+// NewReader returns a new Reader reading from r, packing/unpacking bits
+// MSB-first. It is a shorthand for NewReaderOrder(r, MSBFirst).
+func NewReader(r io.Reader) *Reader {
+	return NewReaderOrder(r, MSBFirst)
+}
+
+// NewReaderOrder returns a new Reader reading from r, using the given bit
+// order to unpack bits from the bytes read from r.
+func NewReaderOrder(r io.Reader, order BitOrder) *Reader {
+	return &Reader{in: toByteReader(r), order: order, MaxUnary: DefaultMaxUnary}
+}
+
+// toByteReader wraps r in readerWrapper, unless it already implements
+// byteReader.
+func toByteReader(r io.Reader) byteReader {
+	if br, ok := r.(byteReader); ok {
+		return br
+	}
+	return readerWrapper{r}
+}
+
+// reset reparents the Reader onto a new underlying byte source, clearing
+// all cached/accumulated state (the bit accumulator, UnreadBits memory,
+// BitsRead and any sticky error), but preserving caller-set fields like
+// order and MaxUnary. Used by ReaderAt.SeekBits to reposition without
+// losing configuration.
+func (r *Reader) reset(src io.Reader) {
+	r.in = toByteReader(src)
+	r.bitBuf, r.bitCnt = 0, 0
+	r.lastVal, r.lastN = 0, 0
+	r.bitsRead = 0
+	r.err = nil
+}
+
+// ReadBits reads n bits and returns them as the least significant bits of u.
+func (r *Reader) ReadBits(n uint8) (u uint64, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	var got uint8
+	for got < n {
+		take := n - got
+		if take > MaxPeekBits {
+			take = MaxPeekBits
+		}
+
+		if err = r.fill(take); err != nil {
+			r.err = err
+			return 0, err
+		}
+
+		v := r.consume(take)
+		if r.order == LSBFirst {
+			u |= v << got
+		} else {
+			u = u<<take | v
+		}
+		got += take
+	}
+
+	return u, nil
+}
+
+// ReadBool reads the next bit and returns whether it is set.
+func (r *Reader) ReadBool() (b bool, err error) {
+	u, err := r.ReadBits(1)
+	return u != 0, err
+}
+
+// ReadByte reads the next 8 bits and returns them as a byte.
+func (r *Reader) ReadByte() (byte, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.bitCnt == 0 {
+		// Bypasses the accumulator, so it can't be undone: a byte read
+		// this way is gone from the source for good.
+		r.lastN = 0
+		b, err := r.in.ReadByte()
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		r.bitsRead += 8
+		return b, nil
+	}
+	u, err := r.ReadBits(8)
+	return byte(u), err
+}
+
+// Read reads len(p) bytes into p, honoring any unaligned bits left over from
+// a previous bit-level read. It implements io.Reader.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.bitCnt == 0 {
+		// Bypasses the accumulator; see the equivalent comment in ReadByte.
+		r.lastN = 0
+		n, err = r.in.Read(p)
+		r.bitsRead += 8 * uint64(n)
+		return n, err
+	}
+
+	for ; n < len(p); n++ {
+		if p[n], err = r.ReadByte(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Align discards the unread bits left over from the last byte read, so the
+// next read starts at a byte boundary. It returns the number of bits
+// discarded.
+func (r *Reader) Align() (skipped byte) {
+	// Whole bytes already pulled into the accumulator (e.g. by PeekBits)
+	// are not part of the current byte and stay unread.
+	skipped = r.bitCnt % 8
+	r.bitCnt -= skipped
+	if r.order == LSBFirst {
+		r.bitBuf >>= skipped
+	}
+	r.lastN = 0
+	return
+}
+
+// BitsRead returns the total number of bits returned to callers so far,
+// through ReadBits, ReadBool, ReadByte, Read, WriteTo, SkipBits or any of the
+// code readers built on top of them. Bits later pushed back with UnreadBits
+// are subtracted back out.
+func (r *Reader) BitsRead() uint64 {
+	return r.bitsRead
+}
+
+// TellBit returns the current bit offset into the stream, i.e. the position
+// the next read will start at. For a plain Reader this is the same as
+// BitsRead; ReaderAt overrides it to report the offset within its section.
+func (r *Reader) TellBit() int64 {
+	return int64(r.bitsRead)
+}
+
+// Writer is a bit-level writer. It writes bits and bytes to an underlying
+// io.Writer, buffering any bits that don't yet fill a whole byte.
+type Writer struct {
+	out   byteWriter
+	order BitOrder
+
+	cache byte // bits not yet written out, aligned according to order
+	bits  byte // number of valid bits buffered in cache
+
+	bitsWritten uint64 // total number of bits accepted from callers so far
+
+	// MaxUnary bounds how many bits WriteUnary (and the codes built on top
+	// of it, e.g. WriteRice) will write for a single value, so a stray
+	// huge value or poorly-chosen Rice parameter can't write for
+	// practically forever. It defaults to DefaultMaxUnary.
+	MaxUnary uint64
+
+	err error
+}
+
+// NewWriter returns a new Writer writing to w, packing bits MSB-first. It is
+// a shorthand for NewWriterOrder(w, MSBFirst).
+func NewWriter(w io.Writer) *Writer {
+	return NewWriterOrder(w, MSBFirst)
+}
+
+// NewWriterOrder returns a new Writer writing to w, using the given bit
+// order to pack bits into the bytes written to w.
+func NewWriterOrder(w io.Writer, order BitOrder) *Writer {
+	bw, ok := w.(byteWriter)
+	if !ok {
+		bw = writerWrapper{w}
+	}
+	return &Writer{out: bw, order: order, MaxUnary: DefaultMaxUnary}
+}
+
+// WriteBits writes the n least significant bits of r.
+func (w *Writer) WriteBits(r uint64, n uint8) (err error) {
+	if w.order == LSBFirst {
+		return w.writeBitsLSB(r, n)
+	}
+	return w.writeBitsMSB(r, n)
+}
+
+func (w *Writer) writeBitsMSB(r uint64, n uint8) (err error) {
+	if w.err != nil {
+		return w.err
+	}
+
+	for n > 0 {
+		free := 8 - w.bits
+		take := n
+		if take > free {
+			take = free
+		}
+
+		v := byte(r>>(n-take)) & (1<<take - 1)
+		w.cache |= v << (free - take)
+		w.bits += take
+		w.bitsWritten += uint64(take)
+		n -= take
+
+		if w.bits == 8 {
+			if err = w.out.WriteByte(w.cache); err != nil {
+				w.err = err
+				return
+			}
+			w.bits, w.cache = 0, 0
+		}
+	}
+
+	return nil
+}
+
+func (w *Writer) writeBitsLSB(r uint64, n uint8) (err error) {
+	if w.err != nil {
+		return w.err
+	}
+
+	for n > 0 {
+		free := 8 - w.bits
+		take := n
+		if take > free {
+			take = free
+		}
+
+		mask := byte(1<<take - 1)
+		w.cache |= byte(r&uint64(mask)) << w.bits
+		r >>= take
+		n -= take
+		w.bits += take
+		w.bitsWritten += uint64(take)
+
+		if w.bits == 8 {
+			if err = w.out.WriteByte(w.cache); err != nil {
+				w.err = err
+				return
+			}
+			w.bits, w.cache = 0, 0
+		}
+	}
+
+	return nil
+}
+
+// WriteBool writes a single bit: 1 if b is true, 0 otherwise.
+func (w *Writer) WriteBool(b bool) (err error) {
+	var v uint64
+	if b {
+		v = 1
+	}
+	if w.order == LSBFirst {
+		return w.writeBitsLSB(v, 1)
+	}
+	return w.writeBitsMSB(v, 1)
+}
+
+// WriteByte writes 8 bits, the bits of c. It implements io.ByteWriter.
+func (w *Writer) WriteByte(c byte) (err error) {
+	if w.err != nil {
+		return w.err
+	}
+	if w.bits == 0 {
+		if err = w.out.WriteByte(c); err != nil {
+			return err
+		}
+		w.bitsWritten += 8
+		return nil
+	}
+	if w.order == LSBFirst {
+		return w.writeUnalignedByteLSB(c)
+	}
+	return w.writeUnalignedByteMSB(c)
+}
+
+func (w *Writer) writeUnalignedByteMSB(c byte) (err error) {
+	bits := w.bits
+	if err = w.out.WriteByte(w.cache | c>>bits); err != nil {
+		w.err = err
+		return
+	}
+	w.cache = c << (8 - bits)
+	w.bitsWritten += 8
+	return nil
+}
+
+func (w *Writer) writeUnalignedByteLSB(c byte) (err error) {
+	bits := w.bits
+	if err = w.out.WriteByte(w.cache | c<<bits); err != nil {
+		w.err = err
+		return
+	}
+	w.cache = c >> (8 - bits)
+	w.bitsWritten += 8
+	return nil
+}
+
+// Write writes len(p) bytes from p, honoring any unaligned bits buffered
+// from a previous bit-level write. It implements io.Writer.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.bits == 0 {
+		n, err = w.out.Write(p)
+		w.bitsWritten += 8 * uint64(n)
+		return n, err
+	}
+
+	for _, c := range p {
+		if err = w.WriteByte(c); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// Align flushes the bits buffered from a previous bit-level write into a
+// full byte (padded with zeros), so the next write starts at a byte
+// boundary. It returns the number of padding bits written.
+func (w *Writer) Align() (skipped byte, err error) {
+	if w.bits == 0 {
+		return 0, nil
+	}
+
+	skipped = 8 - w.bits
+	err = w.out.WriteByte(w.cache)
+	w.bits, w.cache = 0, 0
+	if err != nil {
+		w.err = err
+	}
+
+	return skipped, err
+}
+
+// BitsWritten returns the total number of bits accepted from callers so far,
+// through WriteBits, WriteBool, WriteByte, Write, ReadFrom or any of the code
+// writers built on top of them. Zero-padding written by Align/Close to reach
+// a byte boundary is not counted, since the caller never asked for it.
+func (w *Writer) BitsWritten() uint64 {
+	return w.bitsWritten
+}
+
+// TellBit returns the current bit offset into the stream, i.e. the position
+// the next write will land at (not counting any as-yet-unflushed Align
+// padding).
+func (w *Writer) TellBit() int64 {
+	return int64(w.bitsWritten)
+}
+
+// Close aligns the output to a byte boundary (padding the last, partial
+// byte with zeros) and closes the underlying io.Writer if it implements
+// io.Closer.
+func (w *Writer) Close() (err error) {
+	if _, err = w.Align(); err != nil {
+		return err
+	}
+
+	var out io.Writer = w.out
+	if ww, ok := out.(writerWrapper); ok {
+		out = ww.Writer
+	}
+	if c, ok := out.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -215,3 +216,356 @@ func TestChain(t *testing.T) {
 		expEq(v)(r.ReadBits(bits[i]))
 	}
 }
+
+func TestLSBOrder(t *testing.T) {
+	b := &bytes.Buffer{}
+
+	w := NewWriterOrder(b, LSBFirst)
+
+	expected := []byte{0x5d, 0xab}
+
+	eq, expEq := mighty.EqExpEq(t)
+
+	eq(nil, w.WriteBits(0x1, 1))
+	eq(nil, w.WriteBits(0x0, 1))
+	eq(nil, w.WriteBits(0x7, 3))
+	eq(nil, w.WriteBits(0x2, 3))
+	eq(nil, w.WriteByte(0xab))
+
+	eq(nil, w.Close())
+
+	eq(true, bytes.Equal(b.Bytes(), expected))
+
+	r := NewReaderOrder(bytes.NewBuffer(expected), LSBFirst)
+
+	expEq(uint64(0x1))(r.ReadBits(1))
+	expEq(uint64(0x0))(r.ReadBits(1))
+	expEq(uint64(0x7))(r.ReadBits(3))
+	expEq(uint64(0x2))(r.ReadBits(3))
+	expEq(byte(0xab))(r.ReadByte())
+}
+
+func TestChainLSB(t *testing.T) {
+	eq, expEq := mighty.Eq(t), mighty.ExpEq(t)
+
+	b := &bytes.Buffer{}
+	w := NewWriterOrder(b, LSBFirst)
+
+	rand.Seed(time.Now().UnixNano())
+
+	expected := make([]uint64, 100000)
+	bits := make([]byte, len(expected))
+
+	// Writing (generating)
+	for i := range expected {
+		expected[i] = uint64(rand.Int63())
+		bits[i] = byte(1 + rand.Int31n(60))
+		expected[i] &= uint64(1)<<bits[i] - 1
+		w.WriteBits(expected[i], bits[i])
+	}
+
+	eq(nil, w.Close())
+
+	r := NewReaderOrder(bytes.NewBuffer(b.Bytes()), LSBFirst)
+
+	// Reading (verifying)
+	for i, v := range expected {
+		expEq(v)(r.ReadBits(bits[i]))
+	}
+}
+
+func TestPeekSkipUnread(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+
+	r := NewReader(bytes.NewBuffer([]byte{0xab, 0xcd})) // 1010 1011, 1100 1101
+
+	// Peeking must not advance the Reader.
+	expEq(uint64(0xa))(r.PeekBits(4))
+	expEq(uint64(0xab))(r.PeekBits(8))
+
+	eq(nil, r.SkipBits(4))
+	expEq(uint64(0xb))(r.PeekBits(4))
+
+	expEq(uint64(0xb))(r.ReadBits(4))
+	eq(nil, r.UnreadBits(4))
+	expEq(uint64(0xb))(r.ReadBits(4))
+
+	expEq(byte(0xcd))(r.ReadByte())
+
+	// Unreading a bit count other than the 8 bits ReadByte just took must
+	// fail.
+	_, neq := mighty.EqNeq(t)
+	neq(nil, r.UnreadBits(4))
+}
+
+func TestPeekBitsEOF(t *testing.T) {
+	eq := mighty.Eq(t)
+
+	r := NewReader(bytes.NewBuffer([]byte{0xff}))
+
+	u, err := r.PeekBits(12)
+	eq(uint64(0xff), u)
+	eq(io.ErrUnexpectedEOF, err)
+}
+
+func TestCodes(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+
+	eq(nil, w.WriteUnary(0))
+	eq(nil, w.WriteUnary(5))
+	eq(nil, w.WriteEliasGamma(1))
+	eq(nil, w.WriteEliasGamma(9))
+	eq(nil, w.WriteEliasDelta(1))
+	eq(nil, w.WriteEliasDelta(17))
+	eq(nil, w.WriteRice(0, 3))
+	eq(nil, w.WriteRice(42, 3))
+	eq(nil, w.WriteEliasGammaSigned(0))
+	eq(nil, w.WriteEliasGammaSigned(-5))
+	eq(nil, w.WriteEliasDeltaSigned(5))
+	eq(nil, w.WriteRiceSigned(-42, 4))
+
+	eq(nil, w.Close())
+
+	r := NewReader(bytes.NewBuffer(b.Bytes()))
+
+	expEq(uint64(0))(r.ReadUnary())
+	expEq(uint64(5))(r.ReadUnary())
+	expEq(uint64(1))(r.ReadEliasGamma())
+	expEq(uint64(9))(r.ReadEliasGamma())
+	expEq(uint64(1))(r.ReadEliasDelta())
+	expEq(uint64(17))(r.ReadEliasDelta())
+	expEq(uint64(0))(r.ReadRice(3))
+	expEq(uint64(42))(r.ReadRice(3))
+	expEq(int64(0))(r.ReadEliasGammaSigned())
+	expEq(int64(-5))(r.ReadEliasGammaSigned())
+	expEq(int64(5))(r.ReadEliasDeltaSigned())
+	expEq(int64(-42))(r.ReadRiceSigned(4))
+}
+
+func TestSignedEliasBoundaries(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+
+	eq(nil, w.WriteEliasGammaSigned(math.MinInt64))
+	eq(nil, w.WriteEliasGammaSigned(math.MaxInt64))
+	eq(nil, w.WriteEliasDeltaSigned(math.MinInt64))
+	eq(nil, w.WriteEliasDeltaSigned(math.MaxInt64))
+
+	eq(nil, w.Close())
+
+	r := NewReader(bytes.NewBuffer(b.Bytes()))
+
+	expEq(int64(math.MinInt64))(r.ReadEliasGammaSigned())
+	expEq(int64(math.MaxInt64))(r.ReadEliasGammaSigned())
+	expEq(int64(math.MinInt64))(r.ReadEliasDeltaSigned())
+	expEq(int64(math.MaxInt64))(r.ReadEliasDeltaSigned())
+}
+
+func TestReadUnaryMaxUnary(t *testing.T) {
+	eq := mighty.Eq(t)
+
+	data := make([]byte, 16) // all zero bits, no terminating 1 anywhere
+	r := NewReader(bytes.NewBuffer(data))
+	r.MaxUnary = 32
+
+	_, err := r.ReadUnary()
+	eq(errMaxUnary, err)
+}
+
+func TestWriteUnaryMaxUnary(t *testing.T) {
+	eq := mighty.Eq(t)
+
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	w.MaxUnary = 32
+
+	eq(nil, w.WriteUnary(32))
+	eq(errWriteMaxUnary, w.WriteUnary(33))
+
+	// A Rice parameter too small for v must fail the same way instead of
+	// writing an unbounded unary prefix.
+	eq(errWriteMaxUnary, w.WriteRice(^uint64(0), 0))
+}
+
+func TestWriterReadFrom(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+
+	payload := []byte("the quick brown fox jumps over the lazy dog")
+
+	// Aligned: ReadFrom must take the fast path straight to the
+	// underlying writer.
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	expEq(int64(len(payload)))(w.ReadFrom(bytes.NewReader(payload)))
+	eq(nil, w.Close())
+	eq(true, bytes.Equal(b.Bytes(), payload))
+
+	// Unaligned: the payload must land shifted in by the pending bits.
+	b = &bytes.Buffer{}
+	w = NewWriter(b)
+	eq(nil, w.WriteBits(0x0d, 4)) // 1101
+	expEq(int64(len(payload)))(w.ReadFrom(bytes.NewReader(payload)))
+	eq(nil, w.Close())
+
+	r := NewReader(bytes.NewBuffer(b.Bytes()))
+	expEq(uint64(0x0d))(r.ReadBits(4))
+	got := make([]byte, len(payload))
+	expEq(len(payload))(r.Read(got))
+	eq(true, bytes.Equal(got, payload))
+}
+
+func TestReaderWriteTo(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+
+	payload := []byte("the quick brown fox jumps over the lazy dog")
+
+	// Aligned: WriteTo must take the fast path straight from the
+	// underlying reader.
+	r := NewReader(bytes.NewBuffer(payload))
+	out := &bytes.Buffer{}
+	expEq(int64(len(payload)))(r.WriteTo(out))
+	eq(true, bytes.Equal(out.Bytes(), payload))
+
+	// Unaligned: the bits left over from a previous bit-level read must
+	// start the output, with the rest drained byte by byte across the bit
+	// boundary.
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	eq(nil, w.WriteBits(0x0d, 4))
+	expEq(len(payload))(w.Write(payload))
+	eq(nil, w.Close())
+
+	r = NewReader(bytes.NewBuffer(b.Bytes()))
+	expEq(uint64(0x0d))(r.ReadBits(4))
+	out = &bytes.Buffer{}
+	expEq(int64(len(payload)))(r.WriteTo(out))
+	eq(true, bytes.Equal(out.Bytes(), payload))
+}
+
+func TestBitsReadWritten(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	eq(nil, w.WriteBits(0x05, 3))
+	eq(nil, w.WriteByte(0xff))
+	eq(uint64(11), w.BitsWritten())
+	eq(int64(11), w.TellBit())
+	eq(nil, w.Close()) // padding bits must not be counted
+
+	eq(uint64(11), w.BitsWritten())
+
+	r := NewReader(bytes.NewBuffer(b.Bytes()))
+	expEq(uint64(0x05))(r.ReadBits(3))
+	eq(uint64(3), r.BitsRead())
+	eq(int64(3), r.TellBit())
+
+	eq(nil, r.UnreadBits(3))
+	eq(uint64(0), r.BitsRead())
+}
+
+func TestReaderAtSeekBits(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+
+	// 0xd2, 0x34: 1101 0010 0011 0100
+	data := []byte{0xd2, 0x34}
+	ra := NewReaderAt(bytes.NewReader(data), 0, int64(len(data)))
+
+	expEq(uint64(0x0d))(ra.ReadBits(4)) // 1101
+	expEq(int64(4))(ra.TellBit(), error(nil))
+
+	expEq(int64(9))(ra.SeekBits(9, io.SeekStart))
+	expEq(int64(9))(ra.TellBit(), error(nil))
+	expEq(uint64(0x3))(ra.ReadBits(3)) // bits 9..11: 011
+
+	expEq(int64(10))(ra.SeekBits(-2, io.SeekCurrent))
+	expEq(uint64(0x6))(ra.ReadBits(3)) // bits 10..12: 110
+
+	expEq(int64(8))(ra.SeekBits(-8, io.SeekEnd))
+	expEq(uint64(0x34))(ra.ReadBits(8))
+
+	_, err := ra.SeekBits(1, io.SeekEnd)
+	eq(errSeekBitsRange, err)
+}
+
+func TestReaderAtSeekBitsPreservesMaxUnary(t *testing.T) {
+	eq := mighty.Eq(t)
+
+	data := make([]byte, 16) // all zero bits, no terminating 1 anywhere
+	ra := NewReaderAt(bytes.NewReader(data), 0, int64(len(data)))
+	ra.MaxUnary = 5
+
+	_, err := ra.SeekBits(8, io.SeekStart)
+	eq(nil, err)
+	eq(uint64(5), ra.MaxUnary)
+
+	_, err = ra.ReadUnary()
+	eq(errMaxUnary, err)
+}
+
+func TestSignedBits(t *testing.T) {
+	eq, expEq := mighty.EqExpEq(t)
+	_, neq := mighty.EqNeq(t)
+
+	// n=1: only -1 and 0 fit.
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	eq(nil, w.WriteSignedBits(-1, 1))
+	eq(nil, w.WriteSignedBits(0, 1))
+	neq(nil, w.WriteSignedBits(1, 1))
+	eq(nil, w.Close())
+
+	r := NewReader(bytes.NewBuffer(b.Bytes()))
+	expEq(int64(-1))(r.ReadSignedBits(1))
+	expEq(int64(0))(r.ReadSignedBits(1))
+
+	// n=64: full width, no extension needed.
+	b = &bytes.Buffer{}
+	w = NewWriter(b)
+	eq(nil, w.WriteSignedBits(math.MinInt64, 64))
+	eq(nil, w.WriteSignedBits(math.MaxInt64, 64))
+	eq(nil, w.Close())
+
+	r = NewReader(bytes.NewBuffer(b.Bytes()))
+	expEq(int64(math.MinInt64))(r.ReadSignedBits(64))
+	expEq(int64(math.MaxInt64))(r.ReadSignedBits(64))
+
+	neq(nil, w.WriteSignedBits(-17, 5)) // -17 doesn't fit in 5 signed bits
+}
+
+func TestSignedBitsChain(t *testing.T) {
+	expEq := mighty.ExpEq(t)
+
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+
+	rand.Seed(time.Now().UnixNano())
+
+	expected := make([]int64, 100000)
+	bits := make([]byte, len(expected))
+
+	// Writing (generating)
+	for i := range expected {
+		bits[i] = byte(1 + rand.Int31n(64))
+		raw := uint64(rand.Int63()) & (uint64(1)<<bits[i] - 1)
+		shift := 64 - bits[i]
+		expected[i] = int64(raw<<shift) >> shift // sign-extend to a valid n-bit value
+		if err := w.WriteSignedBits(expected[i], bits[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w.Close()
+
+	r := NewReader(bytes.NewBuffer(b.Bytes()))
+
+	// Reading (verifying)
+	for i, v := range expected {
+		expEq(v)(r.ReadSignedBits(bits[i]))
+	}
+}
@@ -0,0 +1,232 @@
+package bitio
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// DefaultMaxUnary is the default value of a Reader's MaxUnary field.
+const DefaultMaxUnary = 64
+
+// errMaxUnary is returned by ReadUnary (and the codes built on top of it)
+// when more than MaxUnary zero-bits are scanned without finding the
+// terminating 1 bit.
+var errMaxUnary = errors.New("bitio: ReadUnary: MaxUnary bits scanned without a terminator, malformed stream?")
+
+// errEliasZero is returned by WriteEliasGamma and WriteEliasDelta, which
+// can only encode values >= 1.
+var errEliasZero = errors.New("bitio: Elias gamma/delta codes can only encode values >= 1")
+
+// errWriteMaxUnary is returned by WriteUnary (and the codes built on top of
+// it, e.g. WriteRice) when n exceeds w.MaxUnary, so a stray huge value or
+// poorly-chosen Rice parameter can't write for practically forever.
+var errWriteMaxUnary = errors.New("bitio: WriteUnary: n exceeds MaxUnary, malformed value or Rice parameter?")
+
+// ReadUnary reads a unary code: n consecutive zero bits followed by a
+// terminating 1 bit, and returns n. It gives up with an error as soon as it
+// has scanned more than r.MaxUnary zero bits, so a malformed, all-zero
+// stream can't make it loop forever.
+func (r *Reader) ReadUnary() (n uint64, err error) {
+	for {
+		b, err := r.ReadBool()
+		if err != nil {
+			return n, err
+		}
+		if b {
+			return n, nil
+		}
+		if n++; n > r.MaxUnary {
+			return n, errMaxUnary
+		}
+	}
+}
+
+// WriteUnary writes a unary code for n: n zero bits followed by a
+// terminating 1 bit. It returns an error, without writing anything, if n
+// exceeds w.MaxUnary.
+func (w *Writer) WriteUnary(n uint64) (err error) {
+	if n > w.MaxUnary {
+		return errWriteMaxUnary
+	}
+	for ; n > 0; n-- {
+		if err = w.WriteBool(false); err != nil {
+			return err
+		}
+	}
+	return w.WriteBool(true)
+}
+
+// ReadEliasGamma reads an Elias gamma code and returns the decoded value,
+// which is >= 1, except that the code for 1<<64 (written by
+// WriteEliasGammaSigned for math.MinInt64) wraps around to 0.
+func (r *Reader) ReadEliasGamma() (v uint64, err error) {
+	l, err := r.ReadUnary()
+	if err != nil {
+		return 0, err
+	}
+	if l == 0 {
+		return 1, nil
+	}
+	rest, err := r.ReadBits(uint8(l))
+	if err != nil {
+		return 0, err
+	}
+	return 1<<l | rest, nil
+}
+
+// WriteEliasGamma writes the Elias gamma code of v, which must be >= 1.
+func (w *Writer) WriteEliasGamma(v uint64) (err error) {
+	if v == 0 {
+		return errEliasZero
+	}
+	l := bits.Len64(v) - 1
+	if err = w.WriteUnary(uint64(l)); err != nil {
+		return err
+	}
+	if l == 0 {
+		return nil
+	}
+	return w.WriteBits(v&(1<<uint(l)-1), uint8(l))
+}
+
+// ReadEliasDelta reads an Elias delta code and returns the decoded value,
+// which is >= 1, except that the code for 1<<64 (written by
+// WriteEliasDeltaSigned for math.MinInt64) wraps around to 0.
+func (r *Reader) ReadEliasDelta() (v uint64, err error) {
+	l, err := r.ReadEliasGamma()
+	if err != nil {
+		return 0, err
+	}
+	if l == 1 {
+		return 1, nil
+	}
+	rest, err := r.ReadBits(uint8(l - 1))
+	if err != nil {
+		return 0, err
+	}
+	return 1<<(l-1) | rest, nil
+}
+
+// WriteEliasDelta writes the Elias delta code of v, which must be >= 1.
+func (w *Writer) WriteEliasDelta(v uint64) (err error) {
+	if v == 0 {
+		return errEliasZero
+	}
+	l := uint64(bits.Len64(v))
+	if err = w.WriteEliasGamma(l); err != nil {
+		return err
+	}
+	if l == 1 {
+		return nil
+	}
+	return w.WriteBits(v&(1<<(l-1)-1), uint8(l-1))
+}
+
+// ReadRice reads a Golomb-Rice code with parameter k and returns the
+// decoded value.
+func (r *Reader) ReadRice(k uint8) (v uint64, err error) {
+	q, err := r.ReadUnary()
+	if err != nil {
+		return 0, err
+	}
+	if k == 0 {
+		return q, nil
+	}
+	rem, err := r.ReadBits(k)
+	if err != nil {
+		return 0, err
+	}
+	return q<<k | rem, nil
+}
+
+// WriteRice writes the Golomb-Rice code of v with parameter k: v>>k in
+// unary, followed by the low k bits of v.
+func (w *Writer) WriteRice(v uint64, k uint8) (err error) {
+	if err = w.WriteUnary(v >> k); err != nil {
+		return err
+	}
+	if k == 0 {
+		return nil
+	}
+	return w.WriteBits(v&(1<<k-1), k)
+}
+
+// zigZagEncode maps a signed integer to an unsigned one so that small
+// magnitude values (positive or negative) map to small unsigned values:
+// 0, -1, 1, -2, 2, ... become 0, 1, 2, 3, 4, ...
+func zigZagEncode(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+// zigZagDecode reverses zigZagEncode.
+func zigZagDecode(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// ReadEliasGammaSigned reads a signed value encoded with
+// WriteEliasGammaSigned.
+func (r *Reader) ReadEliasGammaSigned() (v int64, err error) {
+	u, err := r.ReadEliasGamma()
+	if err != nil {
+		return 0, err
+	}
+	return zigZagDecode(u - 1), nil
+}
+
+// WriteEliasGammaSigned writes v using the Elias gamma code, zig-zag
+// mapping it to an unsigned value first (shifted by one, since Elias gamma
+// can't encode 0) so that negative values stay compact.
+func (w *Writer) WriteEliasGammaSigned(v int64) (err error) {
+	if v == math.MinInt64 {
+		// zigZagEncode(v)+1 would be 1<<64, one past uint64's range.
+		// Write its gamma code directly instead: 64 zero bits, a
+		// terminating 1, then 64 zero remainder bits.
+		if err = w.WriteUnary(64); err != nil {
+			return err
+		}
+		return w.WriteBits(0, 64)
+	}
+	return w.WriteEliasGamma(zigZagEncode(v) + 1)
+}
+
+// ReadEliasDeltaSigned reads a signed value encoded with
+// WriteEliasDeltaSigned.
+func (r *Reader) ReadEliasDeltaSigned() (v int64, err error) {
+	u, err := r.ReadEliasDelta()
+	if err != nil {
+		return 0, err
+	}
+	return zigZagDecode(u - 1), nil
+}
+
+// WriteEliasDeltaSigned writes v using the Elias delta code, zig-zag
+// mapping it the same way as WriteEliasGammaSigned.
+func (w *Writer) WriteEliasDeltaSigned(v int64) (err error) {
+	if v == math.MinInt64 {
+		// As in WriteEliasGammaSigned, zigZagEncode(v)+1 would be 1<<64.
+		// Its delta code is the gamma code of its 65-bit length (65),
+		// followed by 64 zero remainder bits.
+		if err = w.WriteEliasGamma(65); err != nil {
+			return err
+		}
+		return w.WriteBits(0, 64)
+	}
+	return w.WriteEliasDelta(zigZagEncode(v) + 1)
+}
+
+// ReadRiceSigned reads a signed value encoded with WriteRiceSigned.
+func (r *Reader) ReadRiceSigned(k uint8) (v int64, err error) {
+	u, err := r.ReadRice(k)
+	if err != nil {
+		return 0, err
+	}
+	return zigZagDecode(u), nil
+}
+
+// WriteRiceSigned writes v using the Golomb-Rice code with parameter k,
+// zig-zag mapping it to an unsigned value first so that negative values
+// stay compact.
+func (w *Writer) WriteRiceSigned(v int64, k uint8) (err error) {
+	return w.WriteRice(zigZagEncode(v), k)
+}
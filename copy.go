@@ -0,0 +1,102 @@
+package bitio
+
+import "io"
+
+// readFromBufSize is the chunk size used by ReadFrom's and WriteTo's
+// unaligned, byte-at-a-time slow paths.
+const readFromBufSize = 4096
+
+// ReadFrom reads from r until EOF or error, writing the bytes read. When the
+// Writer is byte-aligned, it forwards directly to the underlying writer
+// (using its ReadFrom if it has one), skipping the per-byte bit shifting
+// entirely. Otherwise each byte read is shifted into the cache one at a
+// time via WriteByte, so Align/Close still produce the same output as if
+// WriteBits/WriteByte had been called directly. It implements io.ReaderFrom.
+func (w *Writer) ReadFrom(r io.Reader) (n int64, err error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if w.bits == 0 {
+		if rf, ok := w.out.(io.ReaderFrom); ok {
+			n, err = rf.ReadFrom(r)
+		} else {
+			n, err = io.Copy(w.out, r)
+		}
+		w.bitsWritten += 8 * uint64(n)
+		if err != nil {
+			w.err = err
+		}
+		return n, err
+	}
+
+	buf := make([]byte, readFromBufSize)
+	for {
+		nr, rerr := r.Read(buf)
+		for i := 0; i < nr; i++ {
+			if err = w.WriteByte(buf[i]); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if rerr != nil {
+			if rerr == io.EOF {
+				return n, nil
+			}
+			return n, rerr
+		}
+	}
+}
+
+// WriteTo writes to w until there's no more data to read or an error
+// occurs. When the Reader is byte-aligned, it forwards directly to the
+// underlying reader (using its WriteTo if it has one), skipping the
+// per-byte bit shifting entirely. Otherwise bytes are pulled one at a time
+// via ReadByte, honoring any bit boundary left over from a previous
+// bit-level read. It implements io.WriterTo.
+func (r *Reader) WriteTo(w io.Writer) (n int64, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	if r.bitCnt == 0 {
+		r.lastN = 0
+		if wt, ok := r.in.(io.WriterTo); ok {
+			n, err = wt.WriteTo(w)
+		} else {
+			n, err = io.Copy(w, r.in)
+		}
+		r.bitsRead += 8 * uint64(n)
+		if err != nil {
+			r.err = err
+		}
+		return n, err
+	}
+
+	buf := make([]byte, readFromBufSize)
+	for {
+		i := 0
+		for ; i < len(buf); i++ {
+			b, rerr := r.ReadByte()
+			if rerr != nil {
+				if i > 0 {
+					nw, werr := w.Write(buf[:i])
+					n += int64(nw)
+					if werr != nil {
+						return n, werr
+					}
+				}
+				if rerr == io.EOF {
+					return n, nil
+				}
+				return n, rerr
+			}
+			buf[i] = b
+		}
+		nw, werr := w.Write(buf)
+		n += int64(nw)
+		if werr != nil {
+			return n, werr
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package bitio
+
+import (
+	"errors"
+	"io"
+)
+
+// errUnreadBits is returned by UnreadBits when n doesn't match the number
+// of bits most recently consumed.
+var errUnreadBits = errors.New("bitio: UnreadBits: n does not match the most recently consumed bit count")
+
+// MaxPeekBits is the maximum number of bits PeekBits and UnreadBits can be
+// called with; SkipBits and ReadBits handle larger counts in chunks of at
+// most this many bits. It is one byte short of the 64-bit
+// accumulator's full width, which leaves fill always room for a whole byte
+// to land without ever having to shift bits off the top.
+const MaxPeekBits = 56
+
+// fill tops up bitBuf with whole bytes read from the source until it holds
+// at least n bits or the source runs out. n must be <= MaxPeekBits: the loop
+// condition below only ever grows bitCnt while there's a free byte's worth
+// of headroom (bitCnt <= 56), so bitCnt never overflows past 64.
+func (r *Reader) fill(n uint8) error {
+	for r.bitCnt < n && r.bitCnt <= MaxPeekBits {
+		b, err := r.in.ReadByte()
+		if err != nil {
+			return err
+		}
+		if r.order == LSBFirst {
+			r.bitBuf |= uint64(b) << r.bitCnt
+		} else {
+			r.bitBuf = r.bitBuf<<8 | uint64(b)
+		}
+		r.bitCnt += 8
+	}
+	return nil
+}
+
+// extract returns the next n cached bits without removing them from bitBuf.
+// The caller must ensure n <= r.bitCnt.
+func (r *Reader) extract(n uint8) uint64 {
+	if n == 0 {
+		return 0
+	}
+	if r.order == LSBFirst {
+		return r.bitBuf & (1<<n - 1)
+	}
+	return (r.bitBuf >> (r.bitCnt - n)) & (1<<n - 1)
+}
+
+// consume removes and returns the next n cached bits. The caller must
+// ensure n <= r.bitCnt. The removed bits are remembered so a single
+// subsequent UnreadBits call can restore them.
+func (r *Reader) consume(n uint8) uint64 {
+	v := r.extract(n)
+	r.lastVal, r.lastN = v, n
+	if r.order == LSBFirst {
+		r.bitBuf >>= n
+	}
+	r.bitCnt -= n
+	r.bitsRead += uint64(n)
+	return v
+}
+
+// PeekBits returns the next n bits without advancing the Reader, so
+// subsequent Peeks and the next Read/Skip still see them. n must be <=
+// MaxPeekBits.
+//
+// If fewer than n bits remain in the source, PeekBits returns whatever bits
+// are available (as the low bits of u) together with io.ErrUnexpectedEOF, so
+// a caller decoding e.g. a Huffman code can still make progress on the last,
+// incomplete code.
+func (r *Reader) PeekBits(n uint8) (u uint64, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	// fill's own error is intentionally not cached in r.err nor returned
+	// as-is: running out of input while merely peeking isn't fatal, the
+	// bits already cached are still perfectly readable.
+	_ = r.fill(n)
+
+	avail := n
+	if r.bitCnt < avail {
+		avail = r.bitCnt
+	}
+	u = r.extract(avail)
+	if avail < n {
+		return u, io.ErrUnexpectedEOF
+	}
+
+	return u, nil
+}
+
+// SkipBits discards the next n bits, as if they had been read and
+// discarded. It is meant to follow a PeekBits call once the caller knows
+// how many of the peeked bits a variable-length code actually used.
+func (r *Reader) SkipBits(n uint64) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	for n > 0 {
+		take := n
+		if take > MaxPeekBits {
+			take = MaxPeekBits
+		}
+
+		if err := r.fill(uint8(take)); err != nil {
+			r.err = err
+			return err
+		}
+		if uint64(r.bitCnt) < take {
+			r.err = io.ErrUnexpectedEOF
+			return r.err
+		}
+
+		r.consume(uint8(take))
+		n -= take
+	}
+
+	return nil
+}
+
+// UnreadBits pushes the n bits most recently consumed by ReadBits,
+// ReadBool, an unaligned ReadByte or Read, or SkipBits back onto the Reader,
+// so the next read sees them again. n must equal the number of bits taken by
+// that most recent step, and UnreadBits can only be called once per such
+// step; it returns an error otherwise. ReadBits and SkipBits consume more
+// than MaxPeekBits bits in chunks, and an unaligned Read consumes one byte
+// at a time; only the last chunk or byte can be unread. A ReadByte, Read or
+// WriteTo that starts byte-aligned bypasses the accumulator and can't be
+// unread.
+func (r *Reader) UnreadBits(n uint8) error {
+	if n == 0 || n != r.lastN {
+		return errUnreadBits
+	}
+
+	if r.order == LSBFirst {
+		r.bitBuf = r.bitBuf<<n | r.lastVal
+	}
+	r.bitCnt += n
+	r.lastN = 0
+	r.bitsRead -= uint64(n)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package bitio
+
+import (
+	"errors"
+	"io"
+)
+
+// errWhence is returned by SeekBits when given an invalid whence value.
+var errWhence = errors.New("bitio: SeekBits: invalid whence")
+
+// errSeekBitsRange is returned by SeekBits when the resulting bit offset
+// would fall outside the section.
+var errSeekBitsRange = errors.New("bitio: SeekBits: resulting bit offset out of range")
+
+// ReaderAt is a random-access bit reader over a fixed section of an
+// underlying io.ReaderAt, e.g. a container format's frame or chunk. Unlike
+// Reader, it can jump straight to an arbitrary bit offset via SeekBits
+// without having to replay everything read before it.
+//
+// It embeds a *Reader, so all of Reader's methods (ReadBits, PeekBits,
+// ReadRice, ...) are available directly on a ReaderAt and operate from the
+// current bit position.
+type ReaderAt struct {
+	*Reader
+
+	ra   io.ReaderAt
+	base int64 // offset of the section within ra
+	size int64 // length of the section, in bytes
+
+	// offset is the absolute bit position corresponding to the embedded
+	// Reader's BitsRead() == 0, i.e. the byte-aligned position SeekBits last
+	// primed it at. TellBit adds the embedded Reader's BitsRead to this.
+	offset int64
+}
+
+// NewReaderAt returns a new ReaderAt reading the n bytes of r starting at
+// off, packing/unpacking bits MSB-first, positioned at bit 0 of the section.
+func NewReaderAt(r io.ReaderAt, off, n int64) *ReaderAt {
+	return NewReaderAtOrder(r, off, n, MSBFirst)
+}
+
+// NewReaderAtOrder is like NewReaderAt, but uses the given bit order to
+// unpack bits from the bytes read.
+func NewReaderAtOrder(r io.ReaderAt, off, n int64, order BitOrder) *ReaderAt {
+	ra := &ReaderAt{ra: r, base: off, size: n}
+	ra.Reader = NewReaderOrder(io.NewSectionReader(r, off, n), order)
+	return ra
+}
+
+// SeekBits sets the bit offset for the next ReadBits (or any other read) to
+// bitOffset, interpreted according to whence: io.SeekStart, io.SeekCurrent
+// or io.SeekEnd. It returns the new absolute bit offset.
+//
+// Internally it resets the Reader onto the part of the section starting at
+// the byte containing the new offset and, if that offset isn't byte-aligned,
+// reads that byte and consumes its leading bits, so the next ReadBits(k)
+// returns the k bits starting exactly at the new offset.
+func (ra *ReaderAt) SeekBits(bitOffset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = bitOffset
+	case io.SeekCurrent:
+		newPos = ra.TellBit() + bitOffset
+	case io.SeekEnd:
+		newPos = ra.size*8 + bitOffset
+	default:
+		return 0, errWhence
+	}
+	if newPos < 0 || newPos > ra.size*8 {
+		return 0, errSeekBitsRange
+	}
+
+	byteOff := newPos / 8
+	bitInByte := uint8(newPos % 8)
+
+	// Reset the existing Reader onto the new section in place, rather than
+	// replacing it with a fresh one, so caller-set fields like MaxUnary
+	// survive the seek.
+	ra.Reader.reset(io.NewSectionReader(ra.ra, ra.base+byteOff, ra.size-byteOff))
+	ra.offset = byteOff * 8
+
+	if bitInByte > 0 {
+		if _, err := ra.Reader.ReadBits(bitInByte); err != nil {
+			return 0, err
+		}
+	}
+
+	return newPos, nil
+}
+
+// TellBit returns the current absolute bit offset within the section, i.e.
+// the position the next read will start at.
+func (ra *ReaderAt) TellBit() int64 {
+	return ra.offset + int64(ra.Reader.BitsRead())
+}
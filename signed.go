@@ -0,0 +1,37 @@
+package bitio
+
+import "errors"
+
+// errSignedBitsRange is returned by WriteSignedBits when v doesn't fit in
+// the requested number of signed bits.
+var errSignedBitsRange = errors.New("bitio: WriteSignedBits: v does not fit in n signed bits")
+
+// ReadSignedBits reads n bits and interprets them as a two's-complement
+// signed integer of that width, sign-extending bit n-1 through bit 63 of
+// the returned value. n must be <= 64.
+func (r *Reader) ReadSignedBits(n uint8) (v int64, err error) {
+	u, err := r.ReadBits(n)
+	if err != nil {
+		return 0, err
+	}
+	shift := 64 - n
+	return int64(u<<shift) >> shift, nil
+}
+
+// WriteSignedBits writes the low n bits of v's two's-complement
+// representation. It returns an error, without writing anything, if v
+// doesn't fit in a signed integer of width n. n must be <= 64.
+func (w *Writer) WriteSignedBits(v int64, n uint8) (err error) {
+	switch {
+	case n == 0:
+		if v != 0 {
+			return errSignedBitsRange
+		}
+	case n < 64:
+		lim := int64(1) << (n - 1)
+		if v < -lim || v >= lim {
+			return errSignedBitsRange
+		}
+	}
+	return w.WriteBits(uint64(v)&(uint64(1)<<n-1), n)
+}